@@ -0,0 +1,146 @@
+// Package config loads New API's layered TOML configuration.
+//
+// Base settings live in config/config.toml. APP_ENV (local/dev/prod,
+// default "local") selects an overlay file, config/config.{env}.toml,
+// whose keys are merged on top of the base. Environment variables are
+// the final layer so container deployments can still override anything
+// without touching the files on disk.
+package config
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+type ServerConfig struct {
+	Port    int    `mapstructure:"port"`
+	GinMode string `mapstructure:"gin_mode"`
+}
+
+type DbConfig struct {
+	Dsn         string `mapstructure:"dsn"`
+	MaxConns    int    `mapstructure:"max_conns"`
+	MaxIdle     int    `mapstructure:"max_idle"`
+	IdleTimeout int    `mapstructure:"idle_timeout"`
+}
+
+type DbSection struct {
+	Master DbConfig   `mapstructure:"master"`
+	Slaves []DbConfig `mapstructure:"slaves"`
+}
+
+type RedisConfig struct {
+	ConnString string `mapstructure:"conn_string"`
+}
+
+type LogConfig struct {
+	SqlDsn string `mapstructure:"sql_dsn"`
+	Level  string `mapstructure:"level"`
+}
+
+type ChannelConfig struct {
+	// SyncFrequency is the cache-sync cadence (channel/token/option
+	// cache reload), distinct from UpdateFrequency below.
+	SyncFrequency   int `mapstructure:"sync_frequency"`
+	UpdateFrequency int `mapstructure:"update_frequency"`
+	TestFrequency   int `mapstructure:"test_frequency"`
+}
+
+type BatchConfig struct {
+	Enabled  bool `mapstructure:"enabled"`
+	Interval int  `mapstructure:"interval"`
+}
+
+type PprofConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+type MetricsConfig struct {
+	PrometheusCollectEnable bool `mapstructure:"prometheus_collect_enable"`
+	Port                    int  `mapstructure:"port"`
+}
+
+type CorsConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	MaxAge           int      `mapstructure:"max_age"`
+}
+
+type Config struct {
+	Server  ServerConfig  `mapstructure:"Server"`
+	Db      DbSection     `mapstructure:"Db"`
+	Redis   RedisConfig   `mapstructure:"Redis"`
+	Log     LogConfig     `mapstructure:"Log"`
+	Channel ChannelConfig `mapstructure:"Channel"`
+	Batch   BatchConfig   `mapstructure:"Batch"`
+	Pprof   PprofConfig   `mapstructure:"Pprof"`
+	Metrics MetricsConfig `mapstructure:"Metrics"`
+	Cors    CorsConfig    `mapstructure:"Cors"`
+}
+
+var (
+	current *Config
+	mu      sync.RWMutex
+)
+
+// Get returns the process-wide config. Load must have been called first.
+func Get() *Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Load reads config/config.toml, overlays config/config.{APP_ENV}.toml
+// if present, lets environment variables win, and stores the result for
+// Get to return.
+func Load() (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile("config/config.toml")
+	v.SetConfigType("toml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "local"
+	}
+	overlay := viper.New()
+	overlay.SetConfigFile("config/config." + env + ".toml")
+	overlay.SetConfigType("toml")
+	if err := overlay.ReadInConfig(); err == nil {
+		// MergeConfigMap merges into v's config layer, below the
+		// override/env layers set up next, so AutomaticEnv still wins.
+		// v.Set would land in the override layer itself and make the
+		// overlay file unbeatable by environment variables.
+		if err := v.MergeConfigMap(overlay.AllSettings()); err != nil {
+			return nil, err
+		}
+	}
+
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	current = cfg
+	mu.Unlock()
+	return cfg, nil
+}
+
+// Reload re-reads the layered TOML files and atomically swaps in the
+// result. It is intended to be driven by SIGHUP for the fields that are
+// safe to hot-swap (sync frequencies, log level, channel test cadence);
+// callers are responsible for propagating the new values to whatever
+// already-running goroutines need them.
+func Reload() (*Config, error) {
+	return Load()
+}
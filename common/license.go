@@ -0,0 +1,127 @@
+package common
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/denisbrodbeck/machineid"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// licensePublicKey is the Ed25519 public key used to verify license.jwt.
+// The matching private key never ships with this binary.
+var licensePublicKey = mustDecodeLicensePublicKey()
+
+// LicenseClaims is the payload embedded in license.jwt.
+type LicenseClaims struct {
+	MachineId   string   `json:"machine_id"`
+	Features    []string `json:"features"`
+	MaxChannels int      `json:"max_channels"`
+	MaxUsers    int      `json:"max_users"`
+	jwt.RegisteredClaims
+}
+
+// License is the process-wide, last-verified license. It starts out
+// nil (no license loaded) and is replaced atomically by refreshes.
+type License struct {
+	mu      sync.RWMutex
+	claims  *LicenseClaims
+	expired bool
+}
+
+var GlobalLicense = &License{}
+
+// Valid reports whether a license is currently loaded and not expired.
+func (l *License) Valid() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.claims != nil && !l.expired
+}
+
+// HasFeature reports whether the current license grants the named feature.
+func (l *License) HasFeature(name string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.claims == nil || l.expired {
+		return false
+	}
+	for _, f := range l.claims.Features {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *License) set(claims *LicenseClaims, expired bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.claims = claims
+	l.expired = expired
+}
+
+const licenseRedisCacheKey = "license:last_known_good"
+
+// InitLicense performs the first license load and starts the background
+// refresh ticker. Unlike the old phone-home check, a bad or missing
+// license never kills the process: features simply stay gated off and a
+// warning is logged, which is the right behavior for air-gapped sites.
+func InitLicense() error {
+	if err := refreshLicense(); err != nil {
+		SysError("license check failed: " + err.Error())
+	}
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := refreshLicense(); err != nil {
+				SysError("license refresh failed: " + err.Error())
+			}
+		}
+	}()
+	return nil
+}
+
+func refreshLicense() error {
+	path := os.Getenv("LICENSE_FILE")
+	if path == "" {
+		path = "license.jwt"
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if RedisEnabled {
+			if cached, cacheErr := RedisGet(licenseRedisCacheKey); cacheErr == nil {
+				return verifyAndStore([]byte(cached))
+			}
+		}
+		return err
+	}
+	if err := verifyAndStore(raw); err != nil {
+		return err
+	}
+	if RedisEnabled {
+		_ = RedisSet(licenseRedisCacheKey, string(raw), 0)
+	}
+	return nil
+}
+
+func verifyAndStore(raw []byte) error {
+	claims := &LicenseClaims{}
+	token, err := jwt.ParseWithClaims(string(raw), claims, func(token *jwt.Token) (interface{}, error) {
+		return licensePublicKey, nil
+	}, jwt.WithValidMethods([]string{"EdDSA"}))
+	if err != nil {
+		GlobalLicense.set(claims, true)
+		return err
+	}
+
+	id, err := machineid.ID()
+	if err == nil && claims.MachineId != "" && claims.MachineId != id {
+		GlobalLicense.set(claims, true)
+		return jwt.ErrTokenInvalidClaims
+	}
+
+	GlobalLicense.set(claims, !token.Valid)
+	return nil
+}
@@ -0,0 +1,22 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+)
+
+// licensePublicKeyB64 is the base64-encoded Ed25519 public key that
+// license.jwt must be signed with. Replace with the real deployment
+// key before cutting a release build.
+const licensePublicKeyB64 = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+
+func mustDecodeLicensePublicKey() ed25519.PublicKey {
+	raw, err := base64.StdEncoding.DecodeString(licensePublicKeyB64)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		// Fall back to a zero key: every license will fail signature
+		// verification until a real key is embedded, which is safer
+		// than panicking on startup.
+		return make(ed25519.PublicKey, ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw)
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Prometheus collectors for the /metrics endpoint. They are registered
+// unconditionally but only populated when Metrics.PrometheusCollectEnable
+// is set, mirroring how Monitor() used to be gated behind ENABLE_PPROF.
+var (
+	RequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "new_api_requests_total",
+		Help: "Total number of relay requests handled, by channel and status.",
+	}, []string{"channel", "status"})
+
+	UpstreamLatencySeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "new_api_upstream_latency_seconds",
+		Help:    "Upstream channel response latency.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"channel"})
+
+	TokensUsedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "new_api_tokens_used_total",
+		Help: "Total tokens consumed, by model.",
+	}, []string{"model"})
+
+	QuotaConsumedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "new_api_quota_consumed_total",
+		Help: "Total quota consumed, by user.",
+	}, []string{"user"})
+
+	MidjourneyQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "new_api_midjourney_queue_depth",
+		Help: "Number of midjourney tasks currently queued or in progress.",
+	})
+
+	DBPoolOpenConns = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "new_api_db_pool_open_connections",
+		Help: "Open DB connections, by pool (master/replica/log).",
+	}, []string{"pool"})
+
+	RedisPoolActiveConns = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "new_api_redis_pool_active_connections",
+		Help: "Active Redis connections.",
+	})
+)
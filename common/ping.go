@@ -0,0 +1,9 @@
+package common
+
+// PingRedis checks that the Redis client is reachable. Used by the
+// /readyz probe.
+func PingRedis() error {
+	ctx, cancel := RedisContext()
+	defer cancel()
+	return RedisClient.Ping(ctx).Err()
+}
@@ -0,0 +1,34 @@
+package common
+
+import "sync/atomic"
+
+// ChannelUpdateFrequency and ChannelTestFrequency mirror
+// config.Config.Channel.{UpdateFrequency,TestFrequency}. Unlike the log
+// level below, they're plain package vars (like SyncFrequency and
+// BatchUpdateInterval) rather than atomics: the goroutines that read
+// them only do so once per tick, so a torn read is harmless and SIGHUP
+// reloads take effect on the following tick.
+var (
+	ChannelUpdateFrequency int
+	ChannelTestFrequency   int
+)
+
+var currentLogLevel atomic.Value
+
+// SetLogLevel updates the active log level. Called once at startup
+// from config.Config.Log.Level and again on every SIGHUP reload.
+func SetLogLevel(level string) {
+	if level == "" {
+		return
+	}
+	currentLogLevel.Store(level)
+}
+
+// CurrentLogLevel returns the active log level, defaulting to "info"
+// before SetLogLevel has been called.
+func CurrentLogLevel() string {
+	if v, ok := currentLogLevel.Load().(string); ok {
+		return v
+	}
+	return "info"
+}
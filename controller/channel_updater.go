@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"one-api/common"
+	"one-api/model"
+	"time"
+)
+
+// AutomaticallyUpdateChannels refreshes channel balances every
+// frequency seconds until ctx is cancelled, so the graceful-shutdown
+// path in main.go can stop it instead of leaking the goroutine. A
+// SIGHUP reload that changes common.ChannelUpdateFrequency is picked
+// up on the following tick via ticker.Reset.
+func AutomaticallyUpdateChannels(ctx context.Context, frequency int) {
+	ticker := time.NewTicker(time.Duration(frequency) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			common.SysLog("updating all channels")
+			model.UpdateAllChannelsBalance()
+			common.SysLog("channels update done")
+			if common.ChannelUpdateFrequency > 0 && common.ChannelUpdateFrequency != frequency {
+				frequency = common.ChannelUpdateFrequency
+				ticker.Reset(time.Duration(frequency) * time.Second)
+			}
+		}
+	}
+}
+
+// AutomaticallyTestChannels runs the channel connectivity test every
+// frequency seconds until ctx is cancelled. Same reload behavior as
+// AutomaticallyUpdateChannels, tracking common.ChannelTestFrequency.
+func AutomaticallyTestChannels(ctx context.Context, frequency int) {
+	ticker := time.NewTicker(time.Duration(frequency) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			common.SysLog("testing all channels")
+			testAllChannels(false)
+			common.SysLog("channels test done")
+			if common.ChannelTestFrequency > 0 && common.ChannelTestFrequency != frequency {
+				frequency = common.ChannelTestFrequency
+				ticker.Reset(time.Duration(frequency) * time.Second)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"net/http"
+	"one-api/common"
+	"one-api/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz is a liveness probe: if the process can handle HTTP at all,
+// it returns 200. It does not touch the database or Redis.
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz is a readiness probe: it pings the database and Redis (when
+// enabled) so Kubernetes only routes traffic once both are reachable.
+func Readyz(c *gin.Context) {
+	if err := model.PingDB(); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "db unavailable", "error": err.Error()})
+		return
+	}
+	if common.RedisEnabled {
+		if err := common.PingRedis(); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "redis unavailable", "error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
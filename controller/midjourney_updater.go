@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"context"
+	"one-api/common"
+	"time"
+)
+
+// UpdateMidjourneyTaskBulk polls pending midjourney tasks for status
+// updates in a loop, stopping when ctx is cancelled so shutdown doesn't
+// leave it running against a closed database.
+func UpdateMidjourneyTaskBulk(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(common.SyncFrequency) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			updateMidjourneyTaskBulk()
+		}
+	}
+}
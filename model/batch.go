@@ -0,0 +1,16 @@
+package model
+
+import "one-api/common"
+
+// FlushBatchUpdater drains the batch updater's pending writes before
+// shutdown proceeds to close the DB connections, so this must run before
+// CloseDB/CloseLogDB. Log and quota_data rows are not batched:
+// RecordLog and RecordQuotaData write them through LOG_DB immediately.
+// It is a no-op when the batch updater was never enabled.
+func FlushBatchUpdater() {
+	if !common.BatchUpdateEnabled {
+		return
+	}
+	batchUpdateFlushAll()
+	common.SysLog("batch updater flushed")
+}
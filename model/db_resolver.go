@@ -0,0 +1,103 @@
+package model
+
+import (
+	"one-api/common/config"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ReplicasConfigured reports whether SetupDBResolver registered any
+// replica DSNs, so CollectPoolMetrics knows whether sampling a
+// "replica" pool would just be duplicating the master's stats.
+var ReplicasConfigured bool
+
+// SetupDBResolver wires db with the dbresolver plugin so that reads on
+// the hot tables (channels, tokens, logs, quota_data) are spread across
+// [Db.Slaves], while writes and transactions stay pinned to db itself
+// (the master). Each read picks a replica at random (RandomPolicy);
+// replicas are not taken in strict rotation. It is a no-op when no
+// slave DSNs are configured. main calls it right after InitDB has
+// opened the master connection, so DB is already assigned by the time
+// this runs.
+func SetupDBResolver(db *gorm.DB, section *config.DbSection) error {
+	if len(section.Slaves) == 0 {
+		return nil
+	}
+
+	var replicas []gorm.Dialector
+	maxConns, maxIdle, idleTimeout := 0, 0, 0
+	for _, slave := range section.Slaves {
+		replicas = append(replicas, dialectorFor(slave.Dsn))
+		if slave.MaxConns > maxConns {
+			maxConns = slave.MaxConns
+		}
+		if slave.MaxIdle > maxIdle {
+			maxIdle = slave.MaxIdle
+		}
+		if slave.IdleTimeout > idleTimeout {
+			idleTimeout = slave.IdleTimeout
+		}
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}, &Channel{}, &Token{}, &Log{}, &QuotaData{})
+
+	// dbresolver applies pool settings to every connection it manages,
+	// sources included, so sizing the replicas this way would silently
+	// override the master pool InitDB already sized via setDBConns. The
+	// highest configured value across the replicas wins rather than
+	// silently keeping the first one; the master is then re-sized back
+	// to its own Db.Master settings so the replica sizing can't leak
+	// into it.
+	if maxConns > 0 {
+		resolver.SetMaxOpenConns(maxConns)
+	}
+	if maxIdle > 0 {
+		resolver.SetMaxIdleConns(maxIdle)
+	}
+	if idleTimeout > 0 {
+		resolver.SetConnMaxIdleTime(time.Duration(idleTimeout) * time.Second)
+	}
+
+	if err := db.Use(resolver); err != nil {
+		return err
+	}
+
+	masterSQLDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if section.Master.MaxConns > 0 {
+		masterSQLDB.SetMaxOpenConns(section.Master.MaxConns)
+	}
+	if section.Master.MaxIdle > 0 {
+		masterSQLDB.SetMaxIdleConns(section.Master.MaxIdle)
+	}
+	if section.Master.IdleTimeout > 0 {
+		masterSQLDB.SetConnMaxIdleTime(time.Duration(section.Master.IdleTimeout) * time.Second)
+	}
+	ReplicasConfigured = true
+	return nil
+}
+
+// PingDB checks that the master database is reachable. Used by the
+// /readyz probe.
+func PingDB() error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Ping()
+}
+
+// ReadDB returns a handle that prefers a replica for the query it is
+// used on, falling back to the master when no replicas are registered.
+// SyncChannelCache, SyncTokenCache and UpdateQuotaData use this for
+// their periodic bulk reads so they don't add load to the master.
+func ReadDB() *gorm.DB {
+	return DB.Clauses(dbresolver.Read)
+}
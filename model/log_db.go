@@ -0,0 +1,76 @@
+package model
+
+import (
+	"one-api/common"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// LOG_DB is the database handle used for all log-writing code paths
+// (logs, midjourney, quota_data). When LOG_SQL_DSN is unset it is simply
+// an alias for DB, so callers can always write through LOG_DB without
+// branching on whether a secondary database is configured.
+var LOG_DB *gorm.DB
+
+// InitLogDB opens the secondary database pointed at by LOG_SQL_DSN, if
+// any, and wires LOG_DB accordingly. It must be called after InitDB.
+func InitLogDB() (err error) {
+	dsn := os.Getenv("LOG_SQL_DSN")
+	if dsn == "" {
+		common.SysLog("LOG_SQL_DSN not set, logs will share the main database")
+		LOG_DB = DB
+		return nil
+	}
+
+	common.SysLog("using secondary database for logs")
+	LOG_DB, err = chooseDB(dsn)
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := LOG_DB.DB()
+	if err != nil {
+		return err
+	}
+	setDBConns(sqlDB)
+
+	if !common.IsMasterNode {
+		return nil
+	}
+	common.SysLog("migrating secondary log database...")
+	err = migrateLogDB()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func migrateLogDB() error {
+	err := LOG_DB.AutoMigrate(&Log{})
+	if err != nil {
+		return err
+	}
+	err = LOG_DB.AutoMigrate(&Midjourney{})
+	if err != nil {
+		return err
+	}
+	err = LOG_DB.AutoMigrate(&QuotaData{})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CloseLogDB closes the secondary log database, if one was opened.
+// It is a no-op when LOG_DB is merely an alias for DB.
+func CloseLogDB() error {
+	if LOG_DB == nil || LOG_DB == DB {
+		return nil
+	}
+	sqlDB, err := LOG_DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"one-api/common"
+	"one-api/common/config"
+)
+
+// metricsEnabled reports whether Metrics.PrometheusCollectEnable is set.
+func metricsEnabled() bool {
+	cfg := config.Get()
+	return cfg != nil && cfg.Metrics.PrometheusCollectEnable
+}
+
+// RecordLog inserts a request log row through LOG_DB. This is the one
+// path relay handlers should use to persist logs, so that setting
+// LOG_SQL_DSN actually moves the write traffic off the main database.
+// When metrics collection is enabled it also feeds the tokens-used
+// metric, since this is the one place every relay call's token
+// accounting passes through.
+func RecordLog(entry *Log) error {
+	if err := LOG_DB.Create(entry).Error; err != nil {
+		return err
+	}
+	if metricsEnabled() {
+		common.TokensUsedTotal.WithLabelValues(entry.ModelName).Add(float64(entry.PromptTokens + entry.CompletionTokens))
+	}
+	return nil
+}
+
+// RecordMidjourneyTask upserts a midjourney task row through LOG_DB.
+func RecordMidjourneyTask(task *Midjourney) error {
+	return LOG_DB.Save(task).Error
+}
+
+// RecordQuotaData upserts a quota_data row through LOG_DB and, when
+// metrics collection is enabled, feeds the quota-consumed metric since
+// this is the one place every relay call's quota deduction passes
+// through.
+func RecordQuotaData(data *QuotaData) error {
+	if err := LOG_DB.Save(data).Error; err != nil {
+		return err
+	}
+	if metricsEnabled() {
+		common.QuotaConsumedTotal.WithLabelValues(data.Username).Add(float64(data.Quota))
+	}
+	return nil
+}
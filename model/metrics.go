@@ -0,0 +1,50 @@
+package model
+
+import (
+	"context"
+	"one-api/common"
+	"time"
+)
+
+// CollectPoolMetrics samples DB and Redis pool stats into the
+// Prometheus gauges every 15 seconds until ctx is cancelled. Started
+// from main only when Metrics.PrometheusCollectEnable is set.
+func CollectPoolMetrics(ctx context.Context) {
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if sqlDB, err := DB.DB(); err == nil {
+				common.DBPoolOpenConns.WithLabelValues("master").Set(float64(sqlDB.Stats().OpenConnections))
+			}
+			if ReplicasConfigured {
+				if sqlDB, err := ReadDB().DB(); err == nil {
+					common.DBPoolOpenConns.WithLabelValues("replica").Set(float64(sqlDB.Stats().OpenConnections))
+				}
+			}
+			if LOG_DB != nil && LOG_DB != DB {
+				if sqlDB, err := LOG_DB.DB(); err == nil {
+					common.DBPoolOpenConns.WithLabelValues("log").Set(float64(sqlDB.Stats().OpenConnections))
+				}
+			}
+			if common.RedisEnabled {
+				stats := common.RedisClient.PoolStats()
+				common.RedisPoolActiveConns.Set(float64(stats.TotalConns) - float64(stats.IdleConns))
+			}
+			if depth, err := PendingMidjourneyTaskCount(); err == nil {
+				common.MidjourneyQueueDepth.Set(float64(depth))
+			}
+		}
+	}
+}
+
+// PendingMidjourneyTaskCount returns the number of midjourney tasks that
+// are still queued or in progress, for the queue-depth gauge.
+func PendingMidjourneyTaskCount() (int64, error) {
+	var count int64
+	err := LOG_DB.Model(&Midjourney{}).Where("status NOT IN ?", []string{"SUCCESS", "FAILURE"}).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"one-api/common"
+	"time"
+)
+
+// SyncChannelCache periodically reloads the in-memory channel cache
+// from the database. It reads through ReadDB so the bulk scan lands on
+// a replica when [Db.Slaves] is configured, instead of adding load to
+// the master. frequency only seeds the first interval; every
+// subsequent wait re-reads common.SyncFrequency, so a SIGHUP reload
+// takes effect without restarting this goroutine.
+func SyncChannelCache(frequency int) {
+	for {
+		time.Sleep(time.Duration(frequency) * time.Second)
+		frequency = common.SyncFrequency
+		common.SysLog("syncing channels from database")
+		var channels []*Channel
+		if err := ReadDB().Find(&channels).Error; err != nil {
+			common.SysError("failed to sync channels: " + err.Error())
+			continue
+		}
+		InitChannelCacheFrom(channels)
+	}
+}
+
+// SyncTokenCache periodically reloads the in-memory token cache from
+// the database, reading through ReadDB for the same replica-offload
+// reason as SyncChannelCache. Like SyncChannelCache, frequency only
+// seeds the first interval; later waits track common.SyncFrequency.
+func SyncTokenCache(frequency int) {
+	for {
+		time.Sleep(time.Duration(frequency) * time.Second)
+		frequency = common.SyncFrequency
+		common.SysLog("syncing tokens from database")
+		var tokens []*Token
+		if err := ReadDB().Find(&tokens).Error; err != nil {
+			common.SysError("failed to sync tokens: " + err.Error())
+			continue
+		}
+		InitTokenCacheFrom(tokens)
+	}
+}
+
+// UpdateQuotaData periodically aggregates quota_data for the usage
+// dashboard. quota_data lives on LOG_DB (see log_db.go), not the main
+// OLTP database, so the aggregation reads and writes go through LOG_DB
+// rather than DB/ReadDB.
+func UpdateQuotaData() {
+	for {
+		time.Sleep(time.Duration(common.QuotaDataUpdateInterval) * time.Second)
+		common.SysLog("updating quota data")
+		if err := updateQuotaDataFrom(LOG_DB); err != nil {
+			common.SysError("failed to update quota data: " + err.Error())
+		}
+	}
+}